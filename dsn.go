@@ -0,0 +1,201 @@
+package smtp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	extDSN  = "DSN"
+	extSize = "SIZE"
+)
+
+// DSNNotify is an RFC 3461 NOTIFY condition for a single recipient.
+type DSNNotify string
+
+const (
+	// DSNNotifyNever requests no DSN for the recipient. It must not be
+	// combined with the other conditions.
+	DSNNotifyNever DSNNotify = "NEVER"
+	// DSNNotifySuccess requests a DSN on successful delivery.
+	DSNNotifySuccess DSNNotify = "SUCCESS"
+	// DSNNotifyFailure requests a DSN on delivery failure.
+	DSNNotifyFailure DSNNotify = "FAILURE"
+	// DSNNotifyDelay requests a DSN when delivery is delayed.
+	DSNNotifyDelay DSNNotify = "DELAY"
+)
+
+// DSNReturn is the RFC 3461 RET parameter, selecting how much of the
+// original message a failure DSN returns.
+type DSNReturn string
+
+const (
+	// DSNReturnFull returns the entire message in a failure DSN.
+	DSNReturnFull DSNReturn = "FULL"
+	// DSNReturnHeaders returns only the headers in a failure DSN.
+	DSNReturnHeaders DSNReturn = "HDRS"
+)
+
+// SendOptions carries RFC 3461 DSN parameters for SendWithOptions. Fields
+// left at their zero value are omitted from the SMTP commands. Options that
+// the server doesn't advertise support for are silently dropped rather than
+// causing an error.
+type SendOptions struct {
+	// Notify lists the delivery conditions to request a DSN for, applied to
+	// every recipient of the message.
+	Notify []DSNNotify
+	// Return selects how much of the message a failure DSN includes.
+	Return DSNReturn
+	// EnvelopeID is an opaque identifier echoed back in any DSN.
+	EnvelopeID string
+	// OriginalRecipient is sent as the ORCPT parameter on every RCPT TO,
+	// for when the recipient address has been rewritten (e.g. by an alias).
+	OriginalRecipient string
+}
+
+// SendWithOptions behaves like Send, but additionally negotiates the DSN and
+// SIZE extensions described by opts when the server advertises them.
+func (c *Client) SendWithOptions(to []string, from string, msg []byte, opts SendOptions) error {
+	if err := validateLine(from); err != nil {
+		return fmt.Errorf("failed to validateLine: %w", err)
+	}
+
+	for _, recp := range to {
+		if err := validateLine(recp); err != nil {
+			return fmt.Errorf("failed to validateLine: %w", err)
+		}
+	}
+
+	if c.client == nil {
+		return ErrClientNotInitialized
+	}
+
+	dsnOK, _ := c.client.Extension(extDSN)
+
+	mailParams, err := c.mailParams(opts, dsnOK, len(msg))
+	if err != nil {
+		return err
+	}
+
+	if err = c.cmdMail(from, mailParams); err != nil {
+		return fmt.Errorf("failed to MAIL FROM: %w", err)
+	}
+
+	for _, addr := range to {
+		var params string
+		if dsnOK {
+			params = rcptParams(opts)
+		}
+
+		if err = c.cmdRcpt(addr, params); err != nil {
+			return fmt.Errorf("failed to RCPT TO: %w", err)
+		}
+	}
+
+	w, err := c.client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to client.Data: %w", err)
+	}
+
+	if _, err = w.Write(msg); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("failed to close message: %w", err)
+	}
+
+	return nil
+}
+
+// mailParams builds the SMTP extension parameters for MAIL FROM: SIZE when
+// the server advertises it, plus RET and ENVID when the server advertises
+// DSN. It returns ErrMessageTooLarge if the server's advertised SIZE limit
+// is smaller than msgLen.
+func (c *Client) mailParams(opts SendOptions, dsnOK bool, msgLen int) (string, error) {
+	sizeOK, sizeParam := c.client.Extension(extSize)
+	return buildMailParams(opts, dsnOK, sizeOK, sizeParam, msgLen)
+}
+
+// buildMailParams is the pure parameter-building core of mailParams, split
+// out so it can be unit tested without a live SMTP connection.
+func buildMailParams(opts SendOptions, dsnOK, sizeOK bool, sizeParam string, msgLen int) (string, error) {
+	var params []string
+
+	if sizeOK {
+		if limit, err := strconv.Atoi(strings.TrimSpace(sizeParam)); err == nil && limit > 0 && msgLen > limit {
+			return "", ErrMessageTooLarge
+		}
+		params = append(params, fmt.Sprintf("SIZE=%d", msgLen))
+	}
+
+	if dsnOK {
+		if opts.Return != "" {
+			params = append(params, "RET="+string(opts.Return))
+		}
+		if opts.EnvelopeID != "" {
+			params = append(params, "ENVID="+opts.EnvelopeID)
+		}
+	}
+
+	return strings.Join(params, " "), nil
+}
+
+// rcptParams builds the RCPT TO extension parameters: NOTIFY and ORCPT.
+func rcptParams(opts SendOptions) string {
+	var params []string
+
+	if len(opts.Notify) > 0 {
+		conditions := make([]string, len(opts.Notify))
+		for i, n := range opts.Notify {
+			conditions[i] = string(n)
+		}
+		params = append(params, "NOTIFY="+strings.Join(conditions, ","))
+	}
+
+	if opts.OriginalRecipient != "" {
+		params = append(params, "ORCPT=rfc822;"+opts.OriginalRecipient)
+	}
+
+	return strings.Join(params, " ")
+}
+
+// cmdMail issues MAIL FROM directly through client.Text, since smtp.Client's
+// own Mail method doesn't accept extension parameters.
+func (c *Client) cmdMail(from, params string) error {
+	cmd := fmt.Sprintf("MAIL FROM:<%s>", from)
+	if params != "" {
+		cmd += " " + params
+	}
+
+	_, _, err := c.cmd(250, cmd)
+	return err
+}
+
+// cmdRcpt issues RCPT TO directly through client.Text, since smtp.Client's
+// own Rcpt method doesn't accept extension parameters.
+func (c *Client) cmdRcpt(addr, params string) error {
+	cmd := fmt.Sprintf("RCPT TO:<%s>", addr)
+	if params != "" {
+		cmd += " " + params
+	}
+
+	// 25 matches both the 250 and 251 success codes RCPT TO may return.
+	_, _, err := c.cmd(25, cmd)
+	return err
+}
+
+// cmd sends a raw command line and reads back its response, the same way
+// smtp.Client's own unexported cmd helper does.
+func (c *Client) cmd(expectCode int, cmd string) (int, string, error) {
+	id, err := c.client.Text.Cmd("%s", cmd)
+	if err != nil {
+		return 0, "", err
+	}
+
+	c.client.Text.StartResponse(id)
+	defer c.client.Text.EndResponse(id)
+
+	return c.client.Text.ReadResponse(expectCode)
+}
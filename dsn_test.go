@@ -0,0 +1,112 @@
+package smtp
+
+import "testing"
+
+func TestRcptParams(t *testing.T) {
+	tests := []struct {
+		name string
+		opts SendOptions
+		want string
+	}{
+		{
+			name: "no options",
+			opts: SendOptions{},
+			want: "",
+		},
+		{
+			name: "notify only",
+			opts: SendOptions{Notify: []DSNNotify{DSNNotifySuccess, DSNNotifyFailure}},
+			want: "NOTIFY=SUCCESS,FAILURE",
+		},
+		{
+			name: "orcpt only",
+			opts: SendOptions{OriginalRecipient: "to@outlook.com"},
+			want: "ORCPT=rfc822;to@outlook.com",
+		},
+		{
+			name: "notify and orcpt",
+			opts: SendOptions{
+				Notify:            []DSNNotify{DSNNotifyDelay},
+				OriginalRecipient: "to@outlook.com",
+			},
+			want: "NOTIFY=DELAY ORCPT=rfc822;to@outlook.com",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requireEqual(t, rcptParams(test.opts), test.want)
+		})
+	}
+}
+
+func TestBuildMailParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      SendOptions
+		dsnOK     bool
+		sizeOK    bool
+		sizeParam string
+		msgLen    int
+		want      string
+		wantErr   error
+	}{
+		{
+			name: "no extensions advertised",
+			opts: SendOptions{Return: DSNReturnFull, EnvelopeID: "env-1"},
+			want: "",
+		},
+		{
+			name:      "size advertised, under limit",
+			sizeOK:    true,
+			sizeParam: " 1000000 ",
+			msgLen:    100,
+			want:      "SIZE=100",
+		},
+		{
+			name:      "size advertised, over limit",
+			sizeOK:    true,
+			sizeParam: "100",
+			msgLen:    200,
+			wantErr:   ErrMessageTooLarge,
+		},
+		{
+			name:   "dsn advertised, return and envelope id",
+			dsnOK:  true,
+			opts:   SendOptions{Return: DSNReturnHeaders, EnvelopeID: "env-1"},
+			msgLen: 10,
+			want:   "RET=HDRS ENVID=env-1",
+		},
+		{
+			name:   "dsn advertised but opts empty",
+			dsnOK:  true,
+			msgLen: 10,
+			want:   "",
+		},
+		{
+			name:      "size and dsn both advertised",
+			sizeOK:    true,
+			sizeParam: "1000",
+			dsnOK:     true,
+			opts:      SendOptions{Return: DSNReturnFull},
+			msgLen:    10,
+			want:      "SIZE=10 RET=FULL",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := buildMailParams(test.opts, test.dsnOK, test.sizeOK, test.sizeParam, test.msgLen)
+			if test.wantErr != nil {
+				if err != test.wantErr {
+					t.Fatalf("got err: %v, want: %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			requireEqual(t, got, test.want)
+		})
+	}
+}
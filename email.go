@@ -2,8 +2,11 @@ package smtp
 
 import (
 	"bytes"
-	"encoding/base64"
-	"fmt"
+	"crypto/rand"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"net/textproto"
 	"strings"
 )
 
@@ -12,16 +15,33 @@ const boundary = "mail-boundary"
 // Email struct contains the parameters necessary to create an email.
 type Email struct {
 	to      []string
+	cc      []string
+	bcc     []string
 	from    string
+	replyTo string
 	subject string
 	body    string
 
+	htmlBody string
+	headers  map[string][]string
+
 	attachments []attachment
+	embeds      []embeddedImage
 }
 
 type attachment struct {
-	name string
-	data []byte
+	name        string
+	data        []byte
+	contentType string
+}
+
+// embeddedImage is an inline attachment referenced from HTMLBody via a
+// "cid:cid" URL.
+type embeddedImage struct {
+	cid         string
+	name        string
+	data        []byte
+	contentType string
 }
 
 // NewEmail is the Email constructor.
@@ -34,7 +54,42 @@ func NewEmail(to []string, from, subject, body string) *Email {
 	}
 }
 
+// SetCc sets the Cc recipients. They are written to the Cc header and
+// receive a RCPT TO command.
+func (e *Email) SetCc(cc []string) {
+	e.cc = cc
+}
+
+// SetBcc sets the Bcc recipients. They receive a RCPT TO command but are
+// never written into the message headers.
+func (e *Email) SetBcc(bcc []string) {
+	e.bcc = bcc
+}
+
+// SetReplyTo sets the Reply-To header.
+func (e *Email) SetReplyTo(replyTo string) {
+	e.replyTo = replyTo
+}
+
+// SetHTMLBody sets an HTML alternative for the email body. When both the
+// plain text body and the HTML body are set, Build/EmailWriter emit a
+// multipart/alternative part nested inside the outer multipart/mixed.
+func (e *Email) SetHTMLBody(html string) {
+	e.htmlBody = html
+}
+
+// SetHeader sets a custom header. It overrides any value Build/EmailWriter
+// would otherwise generate automatically, which currently applies to Date
+// and Message-Id.
+func (e *Email) SetHeader(key string, values ...string) {
+	if e.headers == nil {
+		e.headers = make(map[string][]string)
+	}
+	e.headers[textproto.CanonicalMIMEHeaderKey(key)] = values
+}
+
 // Attach attaches the binary data as a file with given name to the email.
+// Its content type is detected from data.
 func (e *Email) Attach(name string, data []byte) {
 	e.attachments = append(e.attachments, attachment{
 		name: name,
@@ -42,36 +97,125 @@ func (e *Email) Attach(name string, data []byte) {
 	})
 }
 
+// AttachWithType attaches the binary data as a file with given name and
+// content type, overriding detection. contentType is detected from data
+// when left empty, as in Attach.
+func (e *Email) AttachWithType(name string, data []byte, contentType string) {
+	e.attachments = append(e.attachments, attachment{
+		name:        name,
+		data:        data,
+		contentType: contentType,
+	})
+}
+
+// Embed attaches data as an inline image referenced from HTMLBody via a
+// "cid:cid" URL. contentType is detected from data when left empty.
+func (e *Email) Embed(cid, name string, data []byte, contentType string) {
+	e.embeds = append(e.embeds, embeddedImage{
+		cid:         cid,
+		name:        name,
+		data:        data,
+		contentType: contentType,
+	})
+}
+
 // Build returns the email data ready for sending.
 // The email is building according to RFC 2045 (MIME).
 //
-//nolint:revive // Impossible WriteString errors.
+// Build is a thin adapter over EmailWriter kept for backward compatibility;
+// prefer Client.SendEmail, which streams the message instead of buffering it.
 func (e *Email) Build() []byte {
 	var buf bytes.Buffer
 
-	buf.WriteString(fmt.Sprintf("From: %s\r\n", e.from))
-	buf.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(e.to, ";")))
-	buf.WriteString(fmt.Sprintf("Subject: %s\r\n", e.subject))
-
-	buf.WriteString("MIME-Version: 1.0\r\n")
-	buf.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=%s\r\n", boundary))
-	buf.WriteString(fmt.Sprintf("\r\n--%s\r\n", boundary))
-	buf.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
-	buf.WriteString("\r\n" + e.body)
-
-	for _, atch := range e.attachments {
-		buf.WriteString(fmt.Sprintf("\r\n\r\n--%s\r\n", boundary))
-		buf.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
-		buf.WriteString("Content-Transfer-Encoding: base64\r\n")
-		buf.WriteString(fmt.Sprintf("Content-Disposition: attachment; filename=%s\r\n", atch.name))
-		buf.WriteString(fmt.Sprintf("Content-ID: <%s>\r\n\r\n", atch.name))
-
-		b := make([]byte, base64.StdEncoding.EncodedLen(len(atch.data)))
-		base64.StdEncoding.Encode(b, atch.data)
-		buf.Write(b)
+	ew := &EmailWriter{
+		email:           e,
+		mixedBoundary:   boundary,
+		altBoundary:     boundary + "-alt",
+		relatedBoundary: boundary + "-related",
 	}
-
-	buf.WriteString(fmt.Sprintf("\r\n\r\n--%s--", boundary))
+	_, _ = ew.WriteTo(&buf)
 
 	return buf.Bytes()
 }
+
+// recipients returns every RCPT TO address: To, Cc and Bcc combined.
+func (e *Email) recipients() []string {
+	all := make([]string, 0, len(e.to)+len(e.cc)+len(e.bcc))
+	all = append(all, e.to...)
+	all = append(all, e.cc...)
+	all = append(all, e.bcc...)
+
+	return all
+}
+
+// randomHex returns n random bytes hex-encoded.
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// emailHostname returns the domain part of from, used to build a Message-Id.
+func emailHostname(from string) string {
+	if i := strings.LastIndex(from, "@"); i >= 0 && i+1 < len(from) {
+		// Drop the closing bracket of a "Name <user@host>" mailbox.
+		if host := strings.TrimSuffix(strings.TrimSpace(from[i+1:]), ">"); host != "" {
+			return host
+		}
+	}
+
+	return "localhost"
+}
+
+// encodeRFC2047 B-encodes s when it contains non-ASCII characters.
+func encodeRFC2047(s string) string {
+	if isASCII(s) {
+		return s
+	}
+
+	return mime.BEncoding.Encode("utf-8", s)
+}
+
+// encodeAddress RFC 2047-encodes the display name of an "Name <addr>"
+// mailbox, or the whole string when it isn't in that form.
+func encodeAddress(addr string) string {
+	if isASCII(addr) {
+		return addr
+	}
+
+	trimmed := strings.TrimSpace(addr)
+	if i := strings.LastIndex(addr, "<"); i > 0 && strings.HasSuffix(trimmed, ">") {
+		name := strings.TrimSpace(addr[:i])
+		return encodeRFC2047(name) + " " + addr[i:]
+	}
+
+	return encodeRFC2047(addr)
+}
+
+// joinAddresses RFC 2047-encodes each address and joins them per RFC 5322.
+func joinAddresses(addrs []string) string {
+	encoded := make([]string, len(addrs))
+	for i, addr := range addrs {
+		encoded[i] = encodeAddress(addr)
+	}
+
+	return strings.Join(encoded, ", ")
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > 127 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// detectContentType returns the content type for data when the caller didn't specify one.
+func detectContentType(data []byte) string {
+	return http.DetectContentType(data)
+}
@@ -18,6 +18,11 @@ func TestMail(t *testing.T) {
 		from    = "from@outlook.com"
 		subject = "test subject"
 		body    = "test body"
+
+		// Fixed so the expected bytes below are deterministic; Build would
+		// otherwise generate these with the current time and a random ID.
+		testDate      = "Mon, 02 Jan 2006 15:04:05 -0700"
+		testMessageID = "<test@outlook.com>"
 	)
 
 	tests := []struct {
@@ -37,6 +42,8 @@ func TestMail(t *testing.T) {
 			want: []byte(`From: from@outlook.com
 To: to@outlook.com
 Subject: test subject
+Date: Mon, 02 Jan 2006 15:04:05 -0700
+Message-Id: <test@outlook.com>
 MIME-Version: 1.0
 Content-Type: multipart/mixed; boundary=mail-boundary
 
@@ -61,8 +68,10 @@ test body
 				attachments: nil,
 			},
 			want: []byte(`From: from@outlook.com
-To: test-1@outlook.com;test-2@gmail.com;test-3@mail.ru
+To: test-1@outlook.com, test-2@gmail.com, test-3@mail.ru
 Subject: test subject
+Date: Mon, 02 Jan 2006 15:04:05 -0700
+Message-Id: <test@outlook.com>
 MIME-Version: 1.0
 Content-Type: multipart/mixed; boundary=mail-boundary
 
@@ -92,8 +101,10 @@ test body
 				},
 			},
 			want: []byte(`From: from@outlook.com
-To: test-1@outlook.com;test-2@gmail.com;test-3@mail.ru
+To: test-1@outlook.com, test-2@gmail.com, test-3@mail.ru
 Subject: test subject
+Date: Mon, 02 Jan 2006 15:04:05 -0700
+Message-Id: <test@outlook.com>
 MIME-Version: 1.0
 Content-Type: multipart/mixed; boundary=mail-boundary
 
@@ -103,7 +114,7 @@ Content-Type: text/plain; charset="utf-8"
 test body
 
 --mail-boundary
-Content-Type: text/plain; charset="utf-8"
+Content-Type: text/plain; charset=utf-8
 Content-Transfer-Encoding: base64
 Content-Disposition: attachment; filename=attachment_1.txt
 Content-ID: <attachment_1.txt>
@@ -135,8 +146,10 @@ YXR0YWNobWVudF8xLnR4dA==
 				},
 			},
 			want: []byte(`From: from@outlook.com
-To: test-1@outlook.com;test-2@gmail.com;test-3@mail.ru
+To: test-1@outlook.com, test-2@gmail.com, test-3@mail.ru
 Subject: test subject
+Date: Mon, 02 Jan 2006 15:04:05 -0700
+Message-Id: <test@outlook.com>
 MIME-Version: 1.0
 Content-Type: multipart/mixed; boundary=mail-boundary
 
@@ -146,7 +159,7 @@ Content-Type: text/plain; charset="utf-8"
 test body
 
 --mail-boundary
-Content-Type: text/plain; charset="utf-8"
+Content-Type: text/plain; charset=utf-8
 Content-Transfer-Encoding: base64
 Content-Disposition: attachment; filename=attachment_1.txt
 Content-ID: <attachment_1.txt>
@@ -154,7 +167,7 @@ Content-ID: <attachment_1.txt>
 YXR0YWNobWVudF8xLnR4dA==
 
 --mail-boundary
-Content-Type: text/plain; charset="utf-8"
+Content-Type: text/plain; charset=utf-8
 Content-Transfer-Encoding: base64
 Content-Disposition: attachment; filename=attachment_2.txt
 Content-ID: <attachment_2.txt>
@@ -173,6 +186,8 @@ YXR0YWNobWVudF8yLnR4dA==
 				test.fields.subject,
 				test.fields.body,
 			)
+			mail.SetHeader("Date", testDate)
+			mail.SetHeader("Message-Id", testMessageID)
 
 			for _, atch := range test.fields.attachments {
 				mail.Attach(atch.name, atch.data)
@@ -192,6 +207,62 @@ YXR0YWNobWVudF8yLnR4dA==
 	}
 }
 
+func TestEmailCcInHeadersBccNot(t *testing.T) {
+	mail := NewEmail([]string{"to@outlook.com"}, "from@outlook.com", "test subject", "test body")
+	mail.SetCc([]string{"cc@outlook.com"})
+	mail.SetBcc([]string{"bcc@outlook.com"})
+
+	got := string(mail.Build())
+
+	if !strings.Contains(got, "Cc: cc@outlook.com\r\n") {
+		t.Errorf("got mail without Cc header: %s", got)
+	}
+	if strings.Contains(got, "bcc@outlook.com") {
+		t.Errorf("Bcc address leaked into message headers: %s", got)
+	}
+}
+
+func TestEmailHTMLAlternative(t *testing.T) {
+	mail := NewEmail([]string{"to@outlook.com"}, "from@outlook.com", "test subject", "plain body")
+	mail.SetHTMLBody("<p>html body</p>")
+
+	got := string(mail.Build())
+
+	if !strings.Contains(got, "Content-Type: multipart/alternative; boundary=mail-boundary-alt") {
+		t.Errorf("expected a multipart/alternative part, got: %s", got)
+	}
+	if !strings.Contains(got, "Content-Type: text/plain; charset=\"utf-8\"\r\n\r\nplain body") {
+		t.Errorf("missing plain text alternative, got: %s", got)
+	}
+	if !strings.Contains(got, "Content-Type: text/html; charset=\"utf-8\"\r\n\r\n<p>html body</p>") {
+		t.Errorf("missing HTML alternative, got: %s", got)
+	}
+}
+
+func TestEmailEmbedWrapsInRelated(t *testing.T) {
+	mail := NewEmail([]string{"to@outlook.com"}, "from@outlook.com", "test subject", "plain body")
+	mail.Embed("logo", "logo.png", []byte("logo-bytes"), "image/png")
+
+	got := string(mail.Build())
+
+	if !strings.Contains(got, "Content-Type: multipart/related; boundary=mail-boundary-related") {
+		t.Errorf("expected a multipart/related wrapper, got: %s", got)
+	}
+	if !strings.Contains(got, "Content-ID: <logo>\r\n\r\n") {
+		t.Errorf("missing embed Content-ID, got: %s", got)
+	}
+}
+
+func TestEmailSubjectRFC2047(t *testing.T) {
+	mail := NewEmail([]string{"to@outlook.com"}, "from@outlook.com", "тест", "body")
+
+	got := string(mail.Build())
+
+	if !strings.Contains(got, "Subject: =?utf-8?b?") {
+		t.Errorf("expected a B-encoded Subject, got: %s", got)
+	}
+}
+
 func requireEqual(t *testing.T, got, want interface{}) {
 	if got != want {
 		t.Errorf("got: %v, want: %v", got, want)
@@ -0,0 +1,339 @@
+package smtp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// attachmentBufSize is the chunk size used to stream attachment and embed
+// data through the base64 encoder instead of buffering the whole encoded
+// payload.
+const attachmentBufSize = 8 * 1024
+
+// maxHeaderLineLen is the line length Headers are folded at.
+const maxHeaderLineLen = 78
+
+// EmailWriter streams an Email's MIME representation to an io.Writer,
+// base64-encoding attachments and embeds on the fly instead of buffering the
+// whole encoded payload in memory, as Email.Build does.
+type EmailWriter struct {
+	email *Email
+
+	mixedBoundary   string
+	altBoundary     string
+	relatedBoundary string
+}
+
+// NewEmailWriter returns an EmailWriter for e. Each call generates fresh
+// random boundaries so concurrent writes of the same Email can't collide,
+// and so a boundary can't collide with attachment content.
+func NewEmailWriter(e *Email) (*EmailWriter, error) {
+	mixed, err := randomHex(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to randomHex: %w", err)
+	}
+
+	alt, err := randomHex(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to randomHex: %w", err)
+	}
+
+	related, err := randomHex(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to randomHex: %w", err)
+	}
+
+	return &EmailWriter{email: e, mixedBoundary: mixed, altBoundary: alt, relatedBoundary: related}, nil
+}
+
+// WriteTo writes the MIME-encoded email to w and returns the number of bytes written.
+func (ew *EmailWriter) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+
+	if err := ew.writeHeaders(cw); err != nil {
+		return cw.n, err
+	}
+
+	if _, err := fmt.Fprintf(cw, "\r\n--%s\r\n", ew.mixedBoundary); err != nil {
+		return cw.n, err
+	}
+
+	if err := ew.writeContent(cw); err != nil {
+		return cw.n, err
+	}
+
+	if err := ew.writeAttachments(cw); err != nil {
+		return cw.n, err
+	}
+
+	_, err := fmt.Fprintf(cw, "\r\n\r\n--%s--", ew.mixedBoundary)
+
+	return cw.n, err
+}
+
+type headerField struct {
+	name  string
+	value string
+}
+
+func (ew *EmailWriter) writeHeaders(w io.Writer) error {
+	e := ew.email
+
+	fields := []headerField{{"From", encodeAddress(e.from)}}
+
+	if len(e.to) > 0 {
+		fields = append(fields, headerField{"To", joinAddresses(e.to)})
+	}
+	if len(e.cc) > 0 {
+		fields = append(fields, headerField{"Cc", joinAddresses(e.cc)})
+	}
+	if e.replyTo != "" {
+		fields = append(fields, headerField{"Reply-To", encodeAddress(e.replyTo)})
+	}
+
+	fields = append(fields, headerField{"Subject", encodeRFC2047(e.subject)})
+
+	for _, name := range sortedHeaderNames(e.headers) {
+		for _, value := range e.headers[name] {
+			fields = append(fields, headerField{name, value})
+		}
+	}
+
+	if _, ok := e.headers["Date"]; !ok {
+		fields = append(fields, headerField{"Date", time.Now().Format(time.RFC1123Z)})
+	}
+
+	if _, ok := e.headers["Message-Id"]; !ok {
+		id, err := randomHex(8)
+		if err != nil {
+			return fmt.Errorf("failed to randomHex: %w", err)
+		}
+		fields = append(fields, headerField{"Message-Id", fmt.Sprintf("<%s@%s>", id, emailHostname(e.from))})
+	}
+
+	fields = append(fields,
+		headerField{"MIME-Version", "1.0"},
+		headerField{"Content-Type", fmt.Sprintf("multipart/mixed; boundary=%s", ew.mixedBoundary)},
+	)
+
+	for _, f := range fields {
+		if _, err := io.WriteString(w, foldHeader(f.name, f.value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeContent writes the body of the outer multipart/mixed: either the
+// plain/HTML content directly, or that content wrapped in multipart/related
+// alongside embedded images.
+func (ew *EmailWriter) writeContent(cw *countingWriter) error {
+	if len(ew.email.embeds) == 0 {
+		return ew.writeInnerPart(cw)
+	}
+
+	if _, err := fmt.Fprintf(cw, "Content-Type: multipart/related; boundary=%s\r\n\r\n", ew.relatedBoundary); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(cw, "--%s\r\n", ew.relatedBoundary); err != nil {
+		return err
+	}
+	if err := ew.writeInnerPart(cw); err != nil {
+		return err
+	}
+
+	for _, img := range ew.email.embeds {
+		if _, err := fmt.Fprintf(cw, "\r\n\r\n--%s\r\n", ew.relatedBoundary); err != nil {
+			return err
+		}
+		if err := ew.writeEmbed(cw, img); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(cw, "\r\n\r\n--%s--", ew.relatedBoundary)
+
+	return err
+}
+
+// writeInnerPart writes the plain/HTML content: a multipart/alternative
+// when both are set, otherwise whichever single one is set.
+func (ew *EmailWriter) writeInnerPart(w io.Writer) error {
+	e := ew.email
+
+	switch {
+	case e.body != "" && e.htmlBody != "":
+		return ew.writeAlternative(w)
+	case e.htmlBody != "":
+		return writePart(w, `text/html; charset="utf-8"`, e.htmlBody)
+	default:
+		return writePart(w, `text/plain; charset="utf-8"`, e.body)
+	}
+}
+
+func (ew *EmailWriter) writeAlternative(w io.Writer) error {
+	e := ew.email
+
+	if _, err := fmt.Fprintf(w, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", ew.altBoundary); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "--%s\r\n", ew.altBoundary); err != nil {
+		return err
+	}
+	if err := writePart(w, `text/plain; charset="utf-8"`, e.body); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "\r\n\r\n--%s\r\n", ew.altBoundary); err != nil {
+		return err
+	}
+	if err := writePart(w, `text/html; charset="utf-8"`, e.htmlBody); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\r\n\r\n--%s--", ew.altBoundary)
+
+	return err
+}
+
+func writePart(w io.Writer, contentType, body string) error {
+	if _, err := fmt.Fprintf(w, "Content-Type: %s\r\n\r\n", contentType); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, body)
+
+	return err
+}
+
+func (ew *EmailWriter) writeEmbed(w io.Writer, img embeddedImage) error {
+	ct := img.contentType
+	if ct == "" {
+		ct = detectContentType(img.data)
+	}
+
+	if _, err := fmt.Fprintf(w, "Content-Type: %s\r\n", ct); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "Content-Transfer-Encoding: base64\r\n"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Disposition: inline; filename=%s\r\n", img.name); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-ID: <%s>\r\n\r\n", img.cid); err != nil {
+		return err
+	}
+
+	if err := encodeBase64Stream(w, img.data); err != nil {
+		return fmt.Errorf("failed to stream embed %q: %w", img.name, err)
+	}
+
+	return nil
+}
+
+func (ew *EmailWriter) writeAttachments(cw *countingWriter) error {
+	for _, atch := range ew.email.attachments {
+		if _, err := fmt.Fprintf(cw, "\r\n\r\n--%s\r\n", ew.mixedBoundary); err != nil {
+			return err
+		}
+
+		ct := atch.contentType
+		if ct == "" {
+			ct = detectContentType(atch.data)
+		}
+
+		if _, err := fmt.Fprintf(cw, "Content-Type: %s\r\n", ct); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(cw, "Content-Transfer-Encoding: base64\r\n"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(cw, "Content-Disposition: attachment; filename=%s\r\n", atch.name); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(cw, "Content-ID: <%s>\r\n\r\n", atch.name); err != nil {
+			return err
+		}
+
+		if err := encodeBase64Stream(cw, atch.data); err != nil {
+			return fmt.Errorf("failed to stream attachment %q: %w", atch.name, err)
+		}
+	}
+
+	return nil
+}
+
+func encodeBase64Stream(w io.Writer, data []byte) error {
+	buf := make([]byte, attachmentBufSize)
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+
+	// Hide bytes.Reader's WriteTo so CopyBuffer actually copies through buf.
+	src := struct{ io.Reader }{bytes.NewReader(data)}
+	if _, err := io.CopyBuffer(enc, src, buf); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+func sortedHeaderNames(headers map[string][]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// foldHeader returns "name: value\r\n", wrapping value at whitespace onto
+// continuation lines (indented by one space) so no line exceeds
+// maxHeaderLineLen. A single word longer than that is not split and stays
+// on a line of its own.
+func foldHeader(name, value string) string {
+	prefix := name + ": "
+	words := strings.Fields(value)
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	lineLen := len(prefix)
+
+	for i, word := range words {
+		switch {
+		case i == 0:
+		case lineLen+1+len(word) > maxHeaderLineLen:
+			b.WriteString("\r\n ")
+			lineLen = 1
+		default:
+			b.WriteString(" ")
+			lineLen++
+		}
+
+		b.WriteString(word)
+		lineLen += len(word)
+	}
+
+	b.WriteString("\r\n")
+
+	return b.String()
+}
+
+// countingWriter wraps an io.Writer, tracking the number of bytes written
+// through it so WriteTo can report its total.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,284 @@
+package smtp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PoolConfig contains parameters to build a Pool.
+type PoolConfig struct {
+	// MaxConns caps the number of connections the Pool opens at once. Values
+	// <= 0 are treated as 1.
+	MaxConns int
+	// MaxIdle caps the number of idle connections kept around for reuse.
+	// Values <= 0 mean no idle connections are kept between sends.
+	MaxIdle int
+	// IdleTimeout, when positive, periodically closes idle connections that
+	// haven't been used for at least this long.
+	IdleTimeout time.Duration
+	// MaxMessagesPerConn, when positive, closes a connection instead of
+	// reusing it once it has sent this many messages.
+	MaxMessagesPerConn int
+}
+
+// pooledClient wraps a Client with the bookkeeping a Pool needs to decide
+// whether to keep reusing it.
+type pooledClient struct {
+	client       *Client
+	messageCount int
+	lastUsed     time.Time
+}
+
+// Pool is a set of reusable SMTP connections, suited to applications sending
+// a high volume of mail concurrently.
+type Pool struct {
+	opts   Options
+	config PoolConfig
+
+	// sem holds one slot per open connection, idle or checked out.
+	sem chan struct{}
+
+	mu     sync.Mutex
+	idle   []*pooledClient
+	idleCh chan struct{} // closed and replaced whenever idle gains an entry, waking every checkout waiter
+	closed bool
+
+	// outstanding counts checked-out connections not yet checked back in, so
+	// Close can wait for in-flight Sends before closing every connection.
+	outstanding sync.WaitGroup
+
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewPool is a Pool constructor.
+func NewPool(opts Options, config PoolConfig) *Pool {
+	if config.MaxConns <= 0 {
+		config.MaxConns = 1
+	}
+
+	p := &Pool{
+		opts:    opts,
+		config:  config,
+		sem:     make(chan struct{}, config.MaxConns),
+		idleCh:  make(chan struct{}),
+		closeCh: make(chan struct{}),
+	}
+
+	if config.IdleTimeout > 0 {
+		p.wg.Add(1)
+		go p.reapIdleLoop()
+	}
+
+	return p
+}
+
+// Send sends a message on a pooled connection: checkout validates a reused
+// connection with NOOP and reconnects on failure, and RSET is issued after
+// every send, failed or not, so the connection starts clean for the next
+// message.
+func (p *Pool) Send(ctx context.Context, to []string, from string, msg []byte) error {
+	pc, err := p.checkout(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to checkout: %w", err)
+	}
+
+	sendErr := pc.client.Send(to, from, msg)
+	if sendErr == nil {
+		pc.messageCount++
+	}
+
+	// RSET regardless of whether Send succeeded, so a single transaction
+	// failure (e.g. one bad recipient) doesn't poison the rest of the
+	// session; the connection is only discarded if RSET itself fails.
+	p.checkin(pc, pc.client.Reset() == nil)
+
+	return sendErr
+}
+
+// Close closes every connection the pool has open, whether idle or still
+// checked out by an in-flight Send, and stops the idle-reaper goroutine. It
+// waits for in-flight Sends to check their connection back in before
+// closing it, and causes any later checkout to fail with ErrPoolClosed.
+func (p *Pool) Close(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.closeOnce.Do(func() { close(p.closeCh) })
+	p.wg.Wait()
+
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	// Wait for every checked-out connection to be checked in; checkin sees
+	// p.closed and closes it directly instead of adding it back to idle.
+	p.outstanding.Wait()
+
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+
+	errCh := make(chan error, len(idle))
+	for _, pc := range idle {
+		pc := pc
+		go func() {
+			err := pc.client.Close()
+			<-p.sem
+			errCh <- err
+		}()
+	}
+
+	var firstErr error
+	for range idle {
+		if err := <-errCh; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// checkout returns a healthy pooledClient, reusing an idle one when
+// possible and dialing a new one otherwise. When MaxConns is already in use
+// and no connection is idle, it waits for either a connection to be checked
+// back in or ctx to be done, retrying once one is.
+func (p *Pool) checkout(ctx context.Context) (*pooledClient, error) {
+	for {
+		pc, wake, closed := p.tryPopIdle()
+		switch {
+		case closed:
+			return nil, ErrPoolClosed
+		case pc != nil:
+			if err := pc.client.EnsureConnected(ctx); err == nil {
+				p.outstanding.Add(1)
+				return pc, nil
+			}
+
+			_ = pc.client.Close()
+			<-p.sem
+			continue
+		}
+
+		select {
+		case p.sem <- struct{}{}:
+			client := NewClient(p.opts)
+			if err := client.Connect(ctx); err != nil {
+				<-p.sem
+				return nil, fmt.Errorf("failed to client.Connect: %w", err)
+			}
+
+			p.outstanding.Add(1)
+			return &pooledClient{client: client}, nil
+		case <-wake:
+			// A connection was just checked in; retry popping idle instead
+			// of waiting for a fresh MaxConns slot.
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// tryPopIdle atomically checks for an idle connection and, when there isn't
+// one, captures the idleCh to wait on - in the same critical section
+// checkin uses to append and signal, so a checkin racing with checkout
+// can't land in between the check and the capture and go unnoticed.
+func (p *Pool) tryPopIdle() (pc *pooledClient, wake chan struct{}, closed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, nil, true
+	}
+
+	if len(p.idle) == 0 {
+		if testHookTryPopIdleEmpty != nil {
+			testHookTryPopIdleEmpty()
+		}
+		return nil, p.idleCh, false
+	}
+
+	pc = p.idle[len(p.idle)-1]
+	p.idle = p.idle[:len(p.idle)-1]
+
+	return pc, nil, false
+}
+
+// testHookTryPopIdleEmpty, when non-nil, runs while tryPopIdle holds p.mu
+// after finding the idle set empty but before returning the wake channel to
+// wait on. Tests use it to force a concurrent checkin to land exactly where
+// a split check-then-capture implementation would miss it.
+var testHookTryPopIdleEmpty func()
+
+// checkin returns pc to the idle set, or closes it when it's unhealthy, over
+// MaxMessagesPerConn, the pool is closed, or the idle set is already at
+// MaxIdle.
+func (p *Pool) checkin(pc *pooledClient, healthy bool) {
+	defer p.outstanding.Done()
+
+	overMessageLimit := p.config.MaxMessagesPerConn > 0 && pc.messageCount >= p.config.MaxMessagesPerConn
+	if !healthy || overMessageLimit {
+		_ = pc.client.Close()
+		<-p.sem
+		return
+	}
+
+	pc.lastUsed = time.Now()
+
+	p.mu.Lock()
+	if p.closed || p.config.MaxIdle <= 0 || len(p.idle) >= p.config.MaxIdle {
+		p.mu.Unlock()
+		_ = pc.client.Close()
+		<-p.sem
+		return
+	}
+	p.idle = append(p.idle, pc)
+	close(p.idleCh)
+	p.idleCh = make(chan struct{})
+	p.mu.Unlock()
+}
+
+func (p *Pool) reapIdleLoop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.IdleTimeout)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.closeCh:
+			return
+		case <-ticker.C:
+			p.evictIdle()
+		}
+	}
+}
+
+// evictIdle closes idle connections that have been unused for at least
+// IdleTimeout.
+func (p *Pool) evictIdle() {
+	cutoff := time.Now().Add(-p.config.IdleTimeout)
+
+	p.mu.Lock()
+	fresh := p.idle[:0]
+	var evicted []*pooledClient
+	for _, pc := range p.idle {
+		if pc.lastUsed.Before(cutoff) {
+			evicted = append(evicted, pc)
+			continue
+		}
+		fresh = append(fresh, pc)
+	}
+	p.idle = fresh
+	p.mu.Unlock()
+
+	for _, pc := range evicted {
+		_ = pc.client.Close()
+		<-p.sem
+	}
+}
@@ -0,0 +1,316 @@
+package smtp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer starts a minimal in-process SMTP server good enough to
+// drive Client/Pool through EHLO/MAIL/RCPT/DATA/NOOP/RSET/QUIT. A RCPT TO
+// for any address in failRecipients is rejected with a 550.
+func fakeSMTPServer(t *testing.T, failRecipients ...string) (host string, port int) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go handleFakeSMTPConn(conn, failRecipients)
+		}
+	}()
+
+	host, portStr, err := net.SplitHostPort(ln.Addr().String())
+	if err != nil {
+		t.Fatalf("SplitHostPort: %v", err)
+	}
+	if _, err := fmt.Sscanf(portStr, "%d", &port); err != nil {
+		t.Fatalf("Sscanf: %v", err)
+	}
+
+	return host, port
+}
+
+func handleFakeSMTPConn(conn net.Conn, failRecipients []string) {
+	defer conn.Close()
+
+	w := bufio.NewWriter(conn)
+	r := bufio.NewReader(conn)
+
+	fmt.Fprintf(w, "220 fake.smtp ESMTP\r\n")
+	w.Flush()
+
+	inData := false
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if inData {
+			if line == "." {
+				inData = false
+				fmt.Fprintf(w, "250 OK\r\n")
+				w.Flush()
+			}
+			continue
+		}
+
+		upper := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(upper, "EHLO"):
+			fmt.Fprintf(w, "250-fake.smtp\r\n250 8BITMIME\r\n")
+		case strings.HasPrefix(upper, "MAIL FROM"):
+			fmt.Fprintf(w, "250 OK\r\n")
+		case strings.HasPrefix(upper, "RCPT TO"):
+			if recipientRejected(line, failRecipients) {
+				fmt.Fprintf(w, "550 no such user\r\n")
+			} else {
+				fmt.Fprintf(w, "250 OK\r\n")
+			}
+		case strings.HasPrefix(upper, "DATA"):
+			fmt.Fprintf(w, "354 Go ahead\r\n")
+			inData = true
+		case strings.HasPrefix(upper, "NOOP"):
+			fmt.Fprintf(w, "250 OK\r\n")
+		case strings.HasPrefix(upper, "RSET"):
+			fmt.Fprintf(w, "250 OK\r\n")
+		case strings.HasPrefix(upper, "QUIT"):
+			fmt.Fprintf(w, "221 Bye\r\n")
+			w.Flush()
+			return
+		default:
+			fmt.Fprintf(w, "500 unrecognized\r\n")
+		}
+		w.Flush()
+	}
+}
+
+func recipientRejected(rcptLine string, failRecipients []string) bool {
+	for _, addr := range failRecipients {
+		if strings.Contains(rcptLine, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPoolCheckoutReusesConnectionCheckedInWhileWaiting(t *testing.T) {
+	host, port := fakeSMTPServer(t)
+
+	pool := NewPool(Options{Host: host, Port: port, AuthType: AuthNone}, PoolConfig{
+		MaxConns: 1,
+		MaxIdle:  1,
+	})
+	defer pool.Close(context.Background())
+
+	pc, err := pool.checkout(context.Background())
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	type result struct {
+		pc  *pooledClient
+		err error
+	}
+	waitDone := make(chan result, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		pc, err := pool.checkout(ctx)
+		waitDone <- result{pc, err}
+	}()
+
+	time.Sleep(100 * time.Millisecond) // let the waiter block on checkout
+	pool.checkin(pc, true)
+
+	select {
+	case res := <-waitDone:
+		if res.err != nil {
+			t.Fatalf("waiting checkout should have reused the checked-in connection, got: %v", res.err)
+		}
+		pool.checkin(res.pc, true)
+	case <-time.After(3 * time.Second):
+		t.Fatal("checkout deadlocked instead of retrying tryPopIdle after checkin")
+	}
+}
+
+// TestPoolCheckoutNoLostWakeup forces a checkin to run concurrently with a
+// waiter inside checkout's idle-check/wake-capture section, using a test
+// hook rather than a sleep, so it deterministically exercises the window a
+// split check-then-capture implementation would miss a concurrent checkin
+// in.
+func TestPoolCheckoutNoLostWakeup(t *testing.T) {
+	host, port := fakeSMTPServer(t)
+
+	pool := NewPool(Options{Host: host, Port: port, AuthType: AuthNone}, PoolConfig{
+		MaxConns: 1,
+		MaxIdle:  1,
+	})
+	defer pool.Close(context.Background())
+
+	pc, err := pool.checkout(context.Background())
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	reachedGap := make(chan struct{})
+	releaseGap := make(chan struct{})
+	testHookTryPopIdleEmpty = func() {
+		close(reachedGap)
+		<-releaseGap
+	}
+	defer func() { testHookTryPopIdleEmpty = nil }()
+
+	type result struct {
+		pc  *pooledClient
+		err error
+	}
+	waitDone := make(chan result, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		pc, err := pool.checkout(ctx)
+		waitDone <- result{pc, err}
+	}()
+
+	<-reachedGap // the waiter is paused inside tryPopIdle's critical section
+
+	checkinDone := make(chan struct{})
+	go func() {
+		pool.checkin(pc, true)
+		close(checkinDone)
+	}()
+
+	// checkin needs p.mu, which the paused waiter holds; it must not be
+	// able to complete until the gap is released.
+	select {
+	case <-checkinDone:
+		t.Fatal("checkin completed while the waiter held p.mu: mutual exclusion is broken")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(releaseGap)
+	<-checkinDone
+
+	select {
+	case res := <-waitDone:
+		if res.err != nil {
+			t.Fatalf("checkout missed the concurrent checkin: %v", res.err)
+		}
+		pool.checkin(res.pc, true)
+	case <-time.After(3 * time.Second):
+		t.Fatal("checkout deadlocked: lost the wakeup from a checkin racing the idle check")
+	}
+}
+
+func TestPoolCheckinMaxIdleZeroDiscardsConnection(t *testing.T) {
+	host, port := fakeSMTPServer(t)
+
+	pool := NewPool(Options{Host: host, Port: port, AuthType: AuthNone}, PoolConfig{
+		MaxConns: 5,
+		// MaxIdle left at zero: docs say no idle connections are kept.
+	})
+	defer pool.Close(context.Background())
+
+	if err := pool.Send(context.Background(), []string{"to@example.com"}, "from@example.com", []byte("hello\r\n")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	pool.mu.Lock()
+	idleCount := len(pool.idle)
+	pool.mu.Unlock()
+
+	if idleCount != 0 {
+		t.Errorf("got %d idle connections with MaxIdle=0, want 0", idleCount)
+	}
+}
+
+func TestPoolSendRSetsConnectionOnRecipientFailure(t *testing.T) {
+	host, port := fakeSMTPServer(t, "bad@example.com")
+
+	pool := NewPool(Options{Host: host, Port: port, AuthType: AuthNone}, PoolConfig{
+		MaxConns: 1,
+		MaxIdle:  1,
+	})
+	defer pool.Close(context.Background())
+
+	err := pool.Send(context.Background(), []string{"bad@example.com"}, "from@example.com", []byte("hello\r\n"))
+	if err == nil {
+		t.Fatal("expected the bad recipient to fail RCPT TO")
+	}
+
+	pool.mu.Lock()
+	idleCount := len(pool.idle)
+	pool.mu.Unlock()
+
+	if idleCount != 1 {
+		t.Fatalf("got %d idle connections after a recoverable failure, want 1 (RSET should keep the connection)", idleCount)
+	}
+
+	if err := pool.Send(context.Background(), []string{"good@example.com"}, "from@example.com", []byte("hello\r\n")); err != nil {
+		t.Fatalf("Send on the recovered connection: %v", err)
+	}
+}
+
+// TestPoolCloseWaitsForCheckedOutConnections verifies Close blocks until an
+// in-flight Send checks its connection back in, then closes it instead of
+// leaking it - every sem slot the pool ever handed out must be released by
+// the time Close returns.
+func TestPoolCloseWaitsForCheckedOutConnections(t *testing.T) {
+	host, port := fakeSMTPServer(t)
+
+	pool := NewPool(Options{Host: host, Port: port, AuthType: AuthNone}, PoolConfig{
+		MaxConns: 2,
+		MaxIdle:  2,
+	})
+
+	pc, err := pool.checkout(context.Background())
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+
+	closeDone := make(chan error, 1)
+	go func() {
+		closeDone <- pool.Close(context.Background())
+	}()
+
+	select {
+	case <-closeDone:
+		t.Fatal("Close returned while a connection was still checked out")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	pool.checkin(pc, true)
+
+	select {
+	case err := <-closeDone:
+		if err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Close never returned after the outstanding connection was checked in")
+	}
+
+	if len(pool.sem) != 0 {
+		t.Errorf("got %d sem slots still held after Close, want 0 (a connection was leaked)", len(pool.sem))
+	}
+
+	if _, err := pool.checkout(context.Background()); err != ErrPoolClosed {
+		t.Errorf("checkout after Close: got err %v, want ErrPoolClosed", err)
+	}
+}
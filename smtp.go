@@ -26,6 +26,63 @@ var (
 	ErrUnsupportedAuthExt   = errors.New("extension is unsupported by SMTP-server, extension: " + extAuth)
 	ErrFoundCRLF            = errors.New("a line must not contain CR or LF")
 	ErrClientNotInitialized = errors.New("client not initialized")
+	ErrUnencryptedAuth      = errors.New("refusing to authenticate over an unencrypted connection")
+	ErrStartTLSUnsupported  = errors.New("server does not advertise STARTTLS and Options.Encryption requires it")
+	ErrMessageTooLarge      = errors.New("message exceeds the server's advertised SIZE limit")
+	ErrPoolClosed           = errors.New("pool is closed")
+)
+
+// UnsupportedAuthMechanismsError is returned when none of the mechanisms
+// advertised by the SMTP server are supported by the client.
+type UnsupportedAuthMechanismsError struct {
+	// Advertised is the list of AUTH mechanisms the server advertised.
+	Advertised []string
+}
+
+func (e *UnsupportedAuthMechanismsError) Error() string {
+	return fmt.Sprintf("none of the server's advertised auth mechanisms are supported, advertised: %s",
+		strings.Join(e.Advertised, ", "))
+}
+
+// AuthType selects the SASL mechanism Client uses to authenticate.
+type AuthType int
+
+const (
+	// AuthAuto negotiates a mechanism from the ones advertised by the server,
+	// preferring the strongest one the client supports.
+	AuthAuto AuthType = iota
+	// AuthPlain uses smtp.PlainAuth.
+	AuthPlain
+	// AuthLogin uses the LOGIN challenge/response mechanism. It requires TLS.
+	AuthLogin
+	// AuthCRAMMD5 uses smtp.CRAMMD5Auth.
+	AuthCRAMMD5
+	// AuthXOAUTH2 uses an OAuth2 bearer token as the password.
+	AuthXOAUTH2
+	// AuthNone disables authentication entirely.
+	AuthNone
+)
+
+// authPreference is the order AuthAuto tries mechanisms in, strongest first.
+var authPreference = []string{"CRAM-MD5", "XOAUTH2", "LOGIN", "PLAIN"}
+
+// Encryption selects how Client secures its connection to the SMTP server.
+type Encryption int
+
+const (
+	// EncryptionStartTLS upgrades the connection with STARTTLS when the
+	// server advertises it, and continues unencrypted otherwise. This is the
+	// zero value, matching the package's historical behavior.
+	EncryptionStartTLS Encryption = iota
+	// EncryptionNone never upgrades the connection, even if the server
+	// advertises STARTTLS.
+	EncryptionNone
+	// EncryptionStartTLSRequired upgrades the connection with STARTTLS and
+	// fails with ErrStartTLSUnsupported if the server doesn't advertise it.
+	EncryptionStartTLSRequired
+	// EncryptionTLS dials with implicit TLS (SMTPS, typically port 465)
+	// instead of issuing STARTTLS.
+	EncryptionTLS
 )
 
 // Client is an SMTP client.
@@ -41,23 +98,103 @@ type Options struct {
 	Port     int
 	Login    string
 	Password string
+
+	// AuthType selects the authentication mechanism. The zero value is AuthAuto.
+	AuthType AuthType
+	// Auth, when set, is a user-supplied smtp.Auth used instead of the
+	// mechanism AuthType selects. It is not used when AuthType is AuthNone.
+	Auth smtp.Auth
+
+	// Encryption selects the TLS wire mode. The zero value is EncryptionStartTLS.
+	Encryption Encryption
+	// TLSConfig, when set, is used instead of the package default for both
+	// STARTTLS and EncryptionTLS. ServerName defaults to Host when empty.
+	TLSConfig *tls.Config
 }
 
 // NewClient is a Client constructor.
 func NewClient(opts Options) *Client {
-	return &Client{
-		opts: opts,
-		auth: smtp.PlainAuth("", opts.Login, opts.Password, opts.Host),
+	c := &Client{opts: opts}
+
+	if opts.Auth != nil {
+		c.auth = opts.Auth
+	} else {
+		c.auth = buildAuth(opts)
+	}
+
+	return c
+}
+
+// buildAuth returns the smtp.Auth for an explicit AuthType.
+// It returns nil for AuthAuto and AuthNone, which are resolved in Connect.
+func buildAuth(opts Options) smtp.Auth {
+	switch opts.AuthType {
+	case AuthPlain:
+		return smtp.PlainAuth("", opts.Login, opts.Password, opts.Host)
+	case AuthLogin:
+		return &loginAuth{login: opts.Login, password: opts.Password}
+	case AuthCRAMMD5:
+		return smtp.CRAMMD5Auth(opts.Login, opts.Password)
+	case AuthXOAUTH2:
+		return &xoauth2Auth{user: opts.Login, token: opts.Password}
+	case AuthAuto, AuthNone:
+		return nil
+	default:
+		return nil
 	}
 }
 
+// selectAuth picks the strongest mechanism in authPreference that the server advertised.
+func selectAuth(advertised []string, opts Options) (smtp.Auth, error) {
+	for _, name := range authPreference {
+		if !mechanismAdvertised(advertised, name) {
+			continue
+		}
+
+		switch name {
+		case "CRAM-MD5":
+			return smtp.CRAMMD5Auth(opts.Login, opts.Password), nil
+		case "XOAUTH2":
+			return &xoauth2Auth{user: opts.Login, token: opts.Password}, nil
+		case "LOGIN":
+			return &loginAuth{login: opts.Login, password: opts.Password}, nil
+		case "PLAIN":
+			return smtp.PlainAuth("", opts.Login, opts.Password, opts.Host), nil
+		}
+	}
+
+	return nil, &UnsupportedAuthMechanismsError{Advertised: advertised}
+}
+
+func mechanismAdvertised(advertised []string, name string) bool {
+	for _, a := range advertised {
+		if strings.EqualFold(a, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // Connect connects to the specified SMTP server address
 // and authenticates the client with the specified login and password.
 func (c *Client) Connect(ctx context.Context) error {
 	addr := net.JoinHostPort(c.opts.Host, strconv.Itoa(c.opts.Port))
 
-	dialer := net.Dialer{Timeout: dialTimeout}
-	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	if c.opts.Encryption == EncryptionTLS {
+		dialer := tls.Dialer{
+			NetDialer: &net.Dialer{Timeout: dialTimeout},
+			Config:    c.tlsConfig(),
+		}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		dialer := net.Dialer{Timeout: dialTimeout}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to dialer.DialContext, address: %s: %w", addr, err)
 	}
@@ -71,21 +208,32 @@ func (c *Client) Connect(ctx context.Context) error {
 		return fmt.Errorf("failed to client.Hello: %w", err)
 	}
 
-	if ok, _ := client.Extension(extStartTLS); ok {
-		config := &tls.Config{
-			ServerName: c.opts.Host,
-			MinVersion: tls.VersionTLS12,
-		}
-		if err = client.StartTLS(config); err != nil {
-			return fmt.Errorf("failed to client.StartTLS: %w", err)
+	if c.opts.Encryption == EncryptionStartTLS || c.opts.Encryption == EncryptionStartTLSRequired {
+		ok, _ := client.Extension(extStartTLS)
+		switch {
+		case ok:
+			if err = client.StartTLS(c.tlsConfig()); err != nil {
+				return fmt.Errorf("failed to client.StartTLS: %w", err)
+			}
+		case c.opts.Encryption == EncryptionStartTLSRequired:
+			return ErrStartTLSUnsupported
 		}
 	}
 
-	if c.auth != nil {
-		if ok, _ := client.Extension(extAuth); !ok {
+	if c.opts.AuthType != AuthNone {
+		ok, params := client.Extension(extAuth)
+		if !ok {
 			return ErrUnsupportedAuthExt
 		}
 
+		if c.auth == nil {
+			auth, authErr := selectAuth(strings.Fields(params), c.opts)
+			if authErr != nil {
+				return authErr
+			}
+			c.auth = auth
+		}
+
 		if err = client.Auth(c.auth); err != nil {
 			return fmt.Errorf("failed to client.Auth: %w", err)
 		}
@@ -114,6 +262,23 @@ func (c *Client) EnsureConnected(ctx context.Context) error {
 
 const dialTimeout = 30 * time.Second
 
+// tlsConfig returns Options.TLSConfig if set, defaulting ServerName to Host
+// when empty, or the package default otherwise.
+func (c *Client) tlsConfig() *tls.Config {
+	if c.opts.TLSConfig != nil {
+		config := c.opts.TLSConfig.Clone()
+		if config.ServerName == "" {
+			config.ServerName = c.opts.Host
+		}
+		return config
+	}
+
+	return &tls.Config{
+		ServerName: c.opts.Host,
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
 // Send sends a message with SMTP commands MAIL, RCPT and DATA.
 func (c *Client) Send(to []string, from string, msg []byte) error {
 	if err := validateLine(from); err != nil {
@@ -158,6 +323,75 @@ func (c *Client) Send(to []string, from string, msg []byte) error {
 	return nil
 }
 
+// SendEmail sends e using SMTP commands MAIL, RCPT and DATA, streaming its
+// MIME representation straight into the DATA writer via EmailWriter instead
+// of buffering the whole message, as Send does.
+func (c *Client) SendEmail(ctx context.Context, e *Email) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := validateLine(e.from); err != nil {
+		return fmt.Errorf("failed to validateLine: %w", err)
+	}
+
+	recipients := e.recipients()
+
+	for _, recp := range recipients {
+		if err := validateLine(recp); err != nil {
+			return fmt.Errorf("failed to validateLine: %w", err)
+		}
+	}
+
+	if c.client == nil {
+		return ErrClientNotInitialized
+	}
+
+	if err := c.client.Mail(e.from); err != nil {
+		return fmt.Errorf("failed to client.Mail: %w", err)
+	}
+
+	for _, addr := range recipients {
+		if err := c.client.Rcpt(addr); err != nil {
+			return fmt.Errorf("failed to client.Rcpt: %w", err)
+		}
+	}
+
+	w, err := c.client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to client.Data: %w", err)
+	}
+
+	ew, err := NewEmailWriter(e)
+	if err != nil {
+		return fmt.Errorf("failed to NewEmailWriter: %w", err)
+	}
+
+	if _, err = ew.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to ew.WriteTo: %w", err)
+	}
+
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("failed to close message: %w", err)
+	}
+
+	return nil
+}
+
+// Reset sends the RSET command, clearing any MAIL/RCPT/DATA state from a
+// previous transaction so the connection can be reused for the next one.
+func (c *Client) Reset() error {
+	if c.client == nil {
+		return ErrClientNotInitialized
+	}
+
+	if err := c.client.Reset(); err != nil {
+		return fmt.Errorf("failed to client.Reset: %w", err)
+	}
+
+	return nil
+}
+
 func (c *Client) Close() error {
 	if c.client != nil {
 		return c.client.Quit()
@@ -0,0 +1,156 @@
+package smtp
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/smtp"
+	"testing"
+)
+
+func TestMechanismAdvertised(t *testing.T) {
+	advertised := []string{"PLAIN", "login", "Cram-Md5"}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "PLAIN", want: true},
+		{name: "LOGIN", want: true},
+		{name: "CRAM-MD5", want: true},
+		{name: "XOAUTH2", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requireEqual(t, mechanismAdvertised(advertised, test.name), test.want)
+		})
+	}
+}
+
+func TestSelectAuth(t *testing.T) {
+	opts := Options{Host: "smtp.outlook.com", Login: "login", Password: "password"}
+
+	tests := []struct {
+		name       string
+		advertised []string
+		wantType   string
+		wantErr    bool
+	}{
+		{
+			name:       "prefers CRAM-MD5 over the rest",
+			advertised: []string{"PLAIN", "LOGIN", "CRAM-MD5", "XOAUTH2"},
+			wantType:   fmt.Sprintf("%T", smtp.CRAMMD5Auth("", "")),
+		},
+		{
+			name:       "falls back to XOAUTH2 when CRAM-MD5 isn't advertised",
+			advertised: []string{"PLAIN", "LOGIN", "XOAUTH2"},
+			wantType:   fmt.Sprintf("%T", &xoauth2Auth{}),
+		},
+		{
+			name:       "falls back to LOGIN when nothing stronger is advertised",
+			advertised: []string{"PLAIN", "LOGIN"},
+			wantType:   fmt.Sprintf("%T", &loginAuth{}),
+		},
+		{
+			name:       "falls back to PLAIN when nothing stronger is advertised",
+			advertised: []string{"PLAIN"},
+			wantType:   fmt.Sprintf("%T", smtp.PlainAuth("", "", "", "")),
+		},
+		{
+			name:       "no supported mechanism advertised",
+			advertised: []string{"NTLM"},
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			auth, err := selectAuth(test.advertised, opts)
+			if test.wantErr {
+				if _, ok := err.(*UnsupportedAuthMechanismsError); !ok {
+					t.Fatalf("got err %v (%T), want *UnsupportedAuthMechanismsError", err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+
+			if got := fmt.Sprintf("%T", auth); got != test.wantType {
+				t.Errorf("got auth of type %s, want %s", got, test.wantType)
+			}
+		})
+	}
+}
+
+func TestClientTLSConfig(t *testing.T) {
+	t.Run("package default when unset", func(t *testing.T) {
+		c := &Client{opts: Options{Host: "smtp.outlook.com"}}
+
+		got := c.tlsConfig()
+
+		requireEqual(t, got.ServerName, "smtp.outlook.com")
+		requireEqual(t, got.MinVersion, uint16(tls.VersionTLS12))
+	})
+
+	t.Run("custom config is used as-is", func(t *testing.T) {
+		c := &Client{opts: Options{
+			Host:      "smtp.outlook.com",
+			TLSConfig: &tls.Config{InsecureSkipVerify: true, ServerName: "override.example.com"},
+		}}
+
+		got := c.tlsConfig()
+
+		requireEqual(t, got.InsecureSkipVerify, true)
+		requireEqual(t, got.ServerName, "override.example.com")
+	})
+
+	t.Run("custom config without ServerName defaults to Host", func(t *testing.T) {
+		c := &Client{opts: Options{
+			Host:      "smtp.outlook.com",
+			TLSConfig: &tls.Config{InsecureSkipVerify: true},
+		}}
+
+		got := c.tlsConfig()
+
+		requireEqual(t, got.ServerName, "smtp.outlook.com")
+	})
+}
+
+func TestBuildAuth(t *testing.T) {
+	base := Options{Host: "smtp.outlook.com", Login: "login", Password: "password"}
+
+	tests := []struct {
+		name     string
+		authType AuthType
+		wantNil  bool
+		wantType string
+	}{
+		{name: "auto resolves later in Connect, not here", authType: AuthAuto, wantNil: true},
+		{name: "none disables auth", authType: AuthNone, wantNil: true},
+		{name: "plain", authType: AuthPlain, wantType: fmt.Sprintf("%T", smtp.PlainAuth("", "", "", ""))},
+		{name: "login", authType: AuthLogin, wantType: fmt.Sprintf("%T", &loginAuth{})},
+		{name: "cram-md5", authType: AuthCRAMMD5, wantType: fmt.Sprintf("%T", smtp.CRAMMD5Auth("", ""))},
+		{name: "xoauth2", authType: AuthXOAUTH2, wantType: fmt.Sprintf("%T", &xoauth2Auth{})},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := base
+			opts.AuthType = test.authType
+
+			auth := buildAuth(opts)
+			if test.wantNil {
+				if auth != nil {
+					t.Errorf("got non-nil auth: %#v", auth)
+				}
+				return
+			}
+
+			if got := fmt.Sprintf("%T", auth); got != test.wantType {
+				t.Errorf("got auth of type %s, want %s", got, test.wantType)
+			}
+		})
+	}
+}
@@ -243,6 +243,63 @@ func TestBuildAndSendMail(t *testing.T) {
 	}
 }
 
+func TestPoolSend(t *testing.T) {
+	t.Parallel()
+
+	pool := smtp.NewPool(opts, smtp.PoolConfig{
+		MaxConns:           2,
+		MaxIdle:            2,
+		MaxMessagesPerConn: 1,
+	})
+	t.Cleanup(func() {
+		err := pool.Close(context.Background())
+		requireNoError(t, err, "pool.Close(...) unexpected error")
+	})
+
+	for i := 0; i < 3; i++ {
+		mail := smtp.NewEmail(
+			[]string{"to@domain.com"},
+			"from@domain.com",
+			"pool send",
+			"test mail body",
+		)
+
+		err := pool.Send(context.Background(), []string{"to@domain.com"}, "from@domain.com", mail.Build())
+		requireNoError(t, err, "pool.Send(...) unexpected error")
+	}
+}
+
+func TestSendWithOptions(t *testing.T) {
+	t.Parallel()
+
+	client := smtp.NewClient(opts)
+	err := client.Connect(context.Background())
+	requireNoError(t, err, "client.Connect(...) unexpected error")
+	t.Cleanup(func() {
+		err := client.Close()
+		requireNoError(t, err, "client.Close() unexpected error")
+	})
+
+	mail := smtp.NewEmail(
+		[]string{"to@domain.com"},
+		"from@domain.com",
+		"send with DSN options",
+		"test mail body",
+	)
+
+	err = client.SendWithOptions(
+		[]string{"to@domain.com"},
+		"from@domain.com",
+		mail.Build(),
+		smtp.SendOptions{
+			Notify:     []smtp.DSNNotify{smtp.DSNNotifySuccess, smtp.DSNNotifyFailure},
+			Return:     smtp.DSNReturnFull,
+			EnvelopeID: "integration-test-envelope",
+		},
+	)
+	requireNoError(t, err, "client.SendWithOptions(...) unexpected error")
+}
+
 var loremIpsum = []byte(`Lorem ipsum dolor sit amet, consectetur adipiscing 
 elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Ut enim 
 ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip 